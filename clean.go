@@ -0,0 +1,87 @@
+package router
+
+// CleanPath returns the canonical form of p: consecutive "/" collapsed
+// to one, "." segments dropped, and ".." segments resolved against
+// their predecessor (a leading "/.." collapses to "/"). If p is
+// already clean, the original string is returned without allocating.
+func CleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if isCleanPath(p) {
+		return p
+	}
+
+	n := len(p)
+	buf := make([]byte, n+1)
+	buf[0] = '/'
+	r := 1
+	w := 1
+
+	if p[0] != '/' {
+		r = 0
+	}
+
+	for r < n {
+		switch {
+		case p[r] == '/':
+			r++
+
+		case p[r] == '.' && r+1 == n:
+			r++
+
+		case p[r] == '.' && p[r+1] == '/':
+			r += 2
+
+		case p[r] == '.' && p[r+1] == '.' && (r+2 == n || p[r+2] == '/'):
+			// ".." segment: drop it and back up over the previous one.
+			r += 3
+			if w > 1 {
+				for w > 1 && buf[w-1] != '/' {
+					w--
+				}
+				if w > 1 {
+					w--
+				}
+			}
+
+		default:
+			if w > 1 {
+				buf[w] = '/'
+				w++
+			}
+			for r < n && p[r] != '/' {
+				buf[w] = p[r]
+				w++
+				r++
+			}
+		}
+	}
+
+	if w == 1 {
+		return "/"
+	}
+	return string(buf[:w])
+}
+
+// isCleanPath reports whether p has no "//", "." or ".." segment for
+// CleanPath to remove, letting CleanPath skip allocating a buffer.
+func isCleanPath(p string) bool {
+	if p[0] != '/' {
+		return false
+	}
+	for i := 0; i < len(p)-1; i++ {
+		if p[i] != '/' {
+			continue
+		}
+		switch {
+		case p[i+1] == '/':
+			return false
+		case p[i+1] == '.' && (i+2 == len(p) || p[i+2] == '/'):
+			return false
+		case p[i+1] == '.' && p[i+2] == '.' && (i+3 == len(p) || p[i+3] == '/'):
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+package router
+
+import "testing"
+
+func TestCleanPath(t *testing.T) {
+	cases := []struct{ path, want string }{
+		{"", "/"},
+		{"/", "/"},
+		{"/a/b/c", "/a/b/c"},
+		{"/a//b/../c", "/a/c"},
+		{"/a/./b", "/a/b"},
+		{"/a/b/..", "/a"},
+		{"/../a/b", "/a/b"},
+		{"a/b", "/a/b"},
+		{"/a/b/.", "/a/b"},
+		{"//a///b", "/a/b"},
+	}
+	for _, c := range cases {
+		if got := CleanPath(c.path); got != c.want {
+			t.Errorf("CleanPath(%q) = %q, want %q", c.path, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Group is a scoped collection of routes sharing a path prefix and a
+// middleware stack. It holds no routes of its own and no lookup
+// structure: Handle and the method helpers prepend the group's prefix,
+// wrap the handler with the group's middleware, and delegate to the
+// owning Router so the radix tree stays the only place routes live.
+type Group struct {
+	router     *Router
+	parent     *Group // nil for a Group created directly from a Router.
+	prefix     string
+	middleware []func(http.Handler) http.Handler
+	routed     bool // true once a route has been registered on g or a descendant, see Use.
+}
+
+// Use appends middleware that wraps every handler subsequently
+// registered on g, directly or through a nested Group. It panics if
+// called after a route has already been registered on g, since routes
+// registered earlier would silently miss it.
+func (g *Group) Use(mw ...func(http.Handler) http.Handler) {
+	if g.routed {
+		panic(fmt.Errorf("router: Use called on group %q after a route was already registered", g.prefix))
+	}
+	g.middleware = append(g.middleware, mw...)
+}
+
+// Group returns a nested Group rooted at g's prefix followed by prefix.
+// Its middleware stack is resolved from the ancestor chain lazily, at
+// Handle time, so middleware g or an ancestor adds after this call but
+// before a route is registered still applies; see chain.
+func (g *Group) Group(prefix string) *Group {
+	return &Group{router: g.router, parent: g, prefix: g.prefix + prefix}
+}
+
+// Inline calls fn with a Group sharing g's prefix, letting fn add
+// middleware scoped to the routes it registers without introducing a
+// new path prefix.
+func (g *Group) Inline(fn func(*Group)) {
+	fn(&Group{router: g.router, parent: g, prefix: g.prefix})
+}
+
+func (g *Group) middlewareCopy() []func(http.Handler) http.Handler {
+	return append([]func(http.Handler) http.Handler(nil), g.middleware...)
+}
+
+// chain returns g's full middleware stack, outermost first, by walking
+// up through g's ancestors. It is resolved fresh on every call rather
+// than cached at Group/Inline creation, so a Use on an ancestor always
+// reaches descendants created before the call, as long as no route has
+// been registered yet (routed, checked by Use, still guards that).
+func (g *Group) chain() []func(http.Handler) http.Handler {
+	if g.parent == nil {
+		return g.middlewareCopy()
+	}
+	return append(g.parent.chain(), g.middleware...)
+}
+
+// Handle adds a route under g's prefix, wrapping handler with g's
+// resolved middleware chain (in the order it was added, outermost
+// first) before delegating to Router.Handle.
+func (g *Group) Handle(method, path string, handler http.Handler) {
+	g.markRouted()
+	chain := g.chain()
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	g.router.Handle(method, g.prefix+path, handler)
+}
+
+// markRouted flags g and every ancestor Group as routed, so a later Use
+// anywhere along the chain that already contributed middleware to this
+// route panics instead of silently dropping it. Router.Handle marks the
+// owning Router itself.
+func (g *Group) markRouted() {
+	for p := g; p != nil; p = p.parent {
+		p.routed = true
+	}
+}
+
+// Get makes a route for GET method.
+func (g *Group) Get(path string, handler http.Handler) {
+	g.Handle(http.MethodGet, path, handler)
+}
+
+// Post makes a route for POST method.
+func (g *Group) Post(path string, handler http.Handler) {
+	g.Handle(http.MethodPost, path, handler)
+}
+
+// Put makes a route for PUT method.
+func (g *Group) Put(path string, handler http.Handler) {
+	g.Handle(http.MethodPut, path, handler)
+}
+
+// Patch makes a route for PATCH method.
+func (g *Group) Patch(path string, handler http.Handler) {
+	g.Handle(http.MethodPatch, path, handler)
+}
+
+// Delete makes a route for DELETE method.
+func (g *Group) Delete(path string, handler http.Handler) {
+	g.Handle(http.MethodDelete, path, handler)
+}
@@ -0,0 +1,39 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGroupMiddlewareAppliesToRouteRegisteredAfterUse reproduces a case
+// where a child Group is created before its parent calls Use: the child's
+// later route must still pick up the parent's middleware, since no route
+// existed anywhere when Use was called.
+func TestGroupMiddlewareAppliesToRouteRegisteredAfterUse(t *testing.T) {
+	rt := New()
+	parent := rt.Group("/api")
+	child := parent.Group("/v1") // created before parent.Use below
+
+	var ran bool
+	parent.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ran = true
+			next.ServeHTTP(w, r)
+		})
+	})
+	child.Get("/x", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/x", nil)
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !ran {
+		t.Fatal("middleware added to parent before child's route registration did not run")
+	}
+}
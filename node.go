@@ -0,0 +1,191 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// node is a single element of the compressed radix tree used to store
+// registered routes. An edge from a parent to n is labelled with n.path,
+// which is either a literal path fragment, the single byte ":"
+// representing a parameter placeholder, or the single byte "*"
+// representing a named catch-all.
+type node struct {
+	path     string // edge label from the parent to this node
+	children []*node
+	handlers map[string]http.Handler // version (see Router.VersionResolver) -> handler; "" is unversioned
+	params   map[string]uint16       // parameter name -> splitPath index, set on terminal nodes
+	re       *regexp.Regexp          // optional constraint for a ":" edge
+	catchAll string                  // name of the catch-all parameter, set on a "*" node
+}
+
+// makeChild ensures the node reachable from n by following path exists,
+// splitting and creating edges along the way, and returns it. Any of
+// params or re that are non-nil, and handler if it is non-nil, are
+// attached to the resulting node under version. isRoot marks path as
+// the tree's own root ("/"), in which case n itself becomes that node
+// instead of gaining a child.
+func (n *node) makeChild(path string, params map[string]uint16, re *regexp.Regexp, version string, handler http.Handler, isRoot bool) *node {
+	if isRoot {
+		if n.path == "" {
+			n.path = path
+		}
+		return n.attach(params, re, version, handler)
+	}
+
+	cur := n
+	for {
+		var matched *node
+		for _, c := range cur.children {
+			cp := commonPrefixLen(c.path, path)
+			if cp == 0 {
+				continue
+			}
+			if cp < len(c.path) {
+				// Split c so the shared prefix becomes its own node.
+				remainder := &node{
+					path:     c.path[cp:],
+					children: c.children,
+					handlers: c.handlers,
+					params:   c.params,
+					re:       c.re,
+					catchAll: c.catchAll,
+				}
+				c.path = c.path[:cp]
+				c.children = []*node{remainder}
+				c.handlers = nil
+				c.params = nil
+				c.re = nil
+				c.catchAll = ""
+			}
+			matched = c
+			break
+		}
+		if matched == nil {
+			child := &node{path: path}
+			cur.children = append(cur.children, child)
+			return child.attach(params, re, version, handler)
+		}
+		path = path[commonPrefixLen(matched.path, path):]
+		if path == "" {
+			return matched.attach(params, re, version, handler)
+		}
+		cur = matched
+	}
+}
+
+// attach sets the non-nil fields onto n and returns it. If handler is
+// non-nil it is registered under version ("" for an unversioned route).
+func (n *node) attach(params map[string]uint16, re *regexp.Regexp, version string, handler http.Handler) *node {
+	if params != nil {
+		n.params = params
+	}
+	if re != nil {
+		n.re = re
+	}
+	if handler != nil {
+		if n.handlers == nil {
+			n.handlers = make(map[string]http.Handler)
+		}
+		n.handlers[version] = handler
+	}
+	return n
+}
+
+// handlerFor returns the handler registered on n for version, falling
+// back to the unversioned handler if there is no exact match.
+func (n *node) handlerFor(version string) (http.Handler, bool) {
+	if version != "" {
+		if h, ok := n.handlers[version]; ok {
+			return h, true
+		}
+	}
+	h, ok := n.handlers[""]
+	return h, ok
+}
+
+// findChild walks the tree looking for the node matching path. Both
+// catch-all styles this package supports — a named "*name" child and a
+// legacy trailing-slash registration (e.g. "/static/") matching
+// anything under its prefix — only match once every literal and ":"
+// sibling has been tried and failed, regardless of the order children
+// were registered in, so a literal route can coexist with (and take
+// precedence over) a catch-all covering the same prefix.
+func (n *node) findChild(path string) *node {
+	if path == "" {
+		return n
+	}
+	var catchAll, wildcard *node
+	for _, c := range n.children {
+		switch {
+		case c.path == "*":
+			catchAll = c
+		case c.path == ":":
+			sep := strings.IndexByte(path, '/')
+			value, rest := path, ""
+			if sep != -1 {
+				value, rest = path[:sep], path[sep:]
+			}
+			if c.re != nil && !c.re.MatchString(value) {
+				continue
+			}
+			if m := c.findChild(rest); m != nil {
+				return m
+			}
+		case strings.HasPrefix(path, c.path):
+			if m := c.findChild(path[len(c.path):]); m != nil {
+				return m
+			}
+			if isWildcard(c.path) {
+				wildcard = c
+			}
+		case isWildcard(c.path) && strings.HasPrefix(path, c.path[:len(c.path)-1]):
+			wildcard = c
+		}
+	}
+	if wildcard != nil {
+		return wildcard
+	}
+	return catchAll
+}
+
+// routeExists reports whether a route is registered for path when
+// looked up from n, the root of a method's tree.
+func (n *node) routeExists(path string) bool {
+	c := n.findChild(path)
+	return c != nil && len(c.handlers) > 0
+}
+
+// string renders the subtree rooted at n for debugging, indented by
+// indent. A node with registered handlers lists their versions ("-" for
+// the unversioned one) alongside its path.
+func (n *node) string(indent string) (s string) {
+	s = indent + n.path
+	if len(n.handlers) > 0 {
+		versions := make([]string, 0, len(n.handlers))
+		for v := range n.handlers {
+			if v == "" {
+				v = "-"
+			}
+			versions = append(versions, v)
+		}
+		sort.Strings(versions)
+		s += " [" + strings.Join(versions, ", ") + "]"
+	}
+	s += "\n"
+	for _, c := range n.children {
+		s += c.string(indent + " ")
+	}
+	return
+}
+
+// commonPrefixLen returns the length of the longest common prefix of a and b.
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
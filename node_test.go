@@ -0,0 +1,90 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestCatchAllYieldsToLiteralSibling checks that a literal route wins
+// over a named catch-all registered under the same prefix, regardless
+// of which one was registered first.
+func TestCatchAllYieldsToLiteralSibling(t *testing.T) {
+	for _, order := range []string{"catchAllFirst", "literalFirst"} {
+		t.Run(order, func(t *testing.T) {
+			rt := New()
+			catchAll := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "catch-all")
+			})
+			literal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "literal")
+			})
+
+			if order == "catchAllFirst" {
+				rt.Get("/assets/*filepath", catchAll)
+				rt.Get("/assets/logo.png", literal)
+			} else {
+				rt.Get("/assets/logo.png", literal)
+				rt.Get("/assets/*filepath", catchAll)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/assets/logo.png", nil)
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Handler"); got != "literal" {
+				t.Fatalf("handler = %q, want %q", got, "literal")
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/assets/other.png", nil)
+			rec = httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Handler"); got != "catch-all" {
+				t.Fatalf("handler for uncovered path = %q, want %q", got, "catch-all")
+			}
+		})
+	}
+}
+
+// TestTrailingSlashWildcardYieldsToLiteralSibling checks the same
+// precedence for the legacy trailing-slash catch-all convention (a
+// route registered as "/static/" matching anything under that prefix),
+// regardless of which one was registered first.
+func TestTrailingSlashWildcardYieldsToLiteralSibling(t *testing.T) {
+	for _, order := range []string{"wildcardFirst", "literalFirst"} {
+		t.Run(order, func(t *testing.T) {
+			rt := New()
+			wildcard := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "wildcard")
+			})
+			literal := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Handler", "literal")
+			})
+
+			if order == "wildcardFirst" {
+				rt.Get("/static/", wildcard)
+				rt.Get("/static/app.css", literal)
+			} else {
+				rt.Get("/static/app.css", literal)
+				rt.Get("/static/", wildcard)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/static/app.css", nil)
+			rec := httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Handler"); got != "literal" {
+				t.Fatalf("handler = %q, want %q", got, "literal")
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/static/missing.css", nil)
+			rec = httptest.NewRecorder()
+			rt.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Handler"); got != "wildcard" {
+				t.Fatalf("handler for uncovered path = %q, want %q", got, "wildcard")
+			}
+		})
+	}
+}
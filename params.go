@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Param is a single path parameter matched for a request.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is the ordered set of path parameters matched for a request,
+// in the order they appear in the route's path.
+type Params []Param
+
+// Get returns the value of the named parameter, or "" if it doesn't exist.
+func (p Params) Get(name string) string {
+	for _, e := range p {
+		if e.Key == name {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// GetInt returns the named parameter parsed as an int.
+func (p Params) GetInt(name string) (int, error) {
+	return strconv.Atoi(p.Get(name))
+}
+
+// ByIndex returns the value of the i-th parameter, or "" if i is out of range.
+func (p Params) ByIndex(i int) string {
+	if i < 0 || i >= len(p) {
+		return ""
+	}
+	return p[i].Value
+}
+
+// ParamsFromContext returns the path parameters matched for the request
+// carried by ctx. The result is empty if ctx carries none.
+func ParamsFromContext(ctx context.Context) Params {
+	params, _ := ctx.Value(contextKeyParams).(Params)
+	return params
+}
+
+// buildParams resolves path against paramsIdx (parameter name -> splitPath
+// index, as stored on a matched node) into a Params ordered by index.
+// catchAll, if non-empty, names the parameter whose value is the
+// remainder of path from its index onward rather than a single segment.
+func buildParams(path string, paramsIdx map[string]uint16, catchAll string) Params {
+	parts := splitPath(path)
+	params := make(Params, 0, len(paramsIdx))
+	for name, idx := range paramsIdx {
+		var value string
+		if name == catchAll {
+			if int(idx) < len(parts) {
+				value = strings.Join(parts[idx:], "/")
+			}
+		} else {
+			value = parts[idx]
+		}
+		params = append(params, Param{Key: name, Value: value})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return paramsIdx[params[i].Key] < paramsIdx[params[j].Key]
+	})
+	return params
+}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestVersionedRoutesXApiVersionHeader checks that the #vN suffix picks
+// the handler matching the client's X-Api-Version header, falling back
+// to the unversioned handler when the header is absent.
+func TestVersionedRoutesXApiVersionHeader(t *testing.T) {
+	rt := New()
+	rt.Get("/widgets#v1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "v1")
+	}))
+	rt.Get("/widgets#v2", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "v2")
+	}))
+	rt.Get("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "default")
+	}))
+
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{"v2", "v2"},
+		{"2", "v2"}, // normalizeVersion prefixes a bare number with "v".
+		{"v1", "v1"},
+		{"", "default"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+		if c.header != "" {
+			req.Header.Set("X-Api-Version", c.header)
+		}
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Handler"); got != c.want {
+			t.Errorf("X-Api-Version %q: handler = %q, want %q", c.header, got, c.want)
+		}
+	}
+}
+
+// TestVersionedRoutesAcceptMediaTypeParameter checks that, absent an
+// X-Api-Version header, the version is read from a "version" parameter
+// on the Accept header's media type.
+func TestVersionedRoutesAcceptMediaTypeParameter(t *testing.T) {
+	rt := New()
+	rt.Get("/widgets#v2", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "v2")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	req.Header.Set("Accept", "application/vnd.api+json; version=2")
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Handler"); got != "v2" {
+		t.Fatalf("handler = %q, want %q", got, "v2")
+	}
+}
+
+// TestVersionedRoutesUnknownVersionNotAcceptable checks that requesting
+// a version with no matching handler and no unversioned fallback yields
+// 406, rather than silently serving a different version.
+func TestVersionedRoutesUnknownVersionNotAcceptable(t *testing.T) {
+	rt := New()
+	rt.Get("/widgets#v1", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+	req.Header.Set("X-Api-Version", "v2")
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+}
+
+// TestVersionedRoutesCustomResolver checks that a Router-supplied
+// VersionResolver overrides the default header/Accept-based resolution.
+func TestVersionedRoutesCustomResolver(t *testing.T) {
+	rt := New()
+	rt.VersionResolver = func(r *http.Request) string {
+		return "v" + r.URL.Query().Get("v")
+	}
+	rt.Get("/widgets#v3", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", "v3")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/widgets?v=3", nil)
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Handler"); got != "v3" {
+		t.Fatalf("handler = %q, want %q", got, "v3")
+	}
+}
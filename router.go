@@ -4,8 +4,10 @@ package router
 import (
 	"context"
 	"fmt"
+	"mime"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -13,14 +15,39 @@ type contextKey int
 
 // Context keys
 const (
-	contextKeyParamsIdx contextKey = iota
-	contextKeyParams
+	contextKeyParams contextKey = iota
 )
 
 // The Router is the main structure of this package.
 type Router struct {
-	NotFoundHandler http.Handler
-	trees           map[string]*node // trees is a map of methods with their path nodes.
+	NotFoundHandler         http.Handler
+	MethodNotAllowedHandler http.Handler                      // used instead of a bare 405 when the path matches under another method.
+	GlobalOPTIONS           http.Handler                      // used for "OPTIONS *" requests, if set and HandleOPTIONS is true.
+	CleanPath               bool                              // if true, ServeHTTP canonicalises the request path before matching.
+	HandleOPTIONS           bool                              // if true, unrouted OPTIONS requests for a path routed under other methods get an automatic 204 reply.
+	VersionResolver         func(*http.Request) string        // resolves the client's requested "#vN" version; defaults to defaultVersionResolver.
+	trees                   map[string]*node                  // trees is a map of methods with their path nodes.
+	middleware              []func(http.Handler) http.Handler // middleware wraps every handler registered on rt.
+	routed                  bool                              // true once a route has been registered, see Use.
+}
+
+// Use appends middleware that wraps every handler subsequently
+// registered on rt, directly or through a Group. Middleware runs in
+// the order it was added, outermost first. It panics if called after a
+// route has already been registered, since routes registered earlier
+// would silently miss it.
+func (rt *Router) Use(mw ...func(http.Handler) http.Handler) {
+	if rt.routed {
+		panic(fmt.Errorf("router: Use called after a route was already registered"))
+	}
+	rt.middleware = append(rt.middleware, mw...)
+}
+
+// Group returns a Group rooted at prefix. Routes registered on it are
+// added to rt's own radix tree, which remains the only lookup
+// structure, wrapped by the group's middleware stack.
+func (rt *Router) Group(prefix string) *Group {
+	return &Group{router: rt, prefix: prefix}
 }
 
 // New returns a fresh rounting unit.
@@ -40,12 +67,25 @@ func (rt *Router) String() (s string) {
 	return
 }
 
-// Handle adds a route with method, path and handler.
+// Handle adds a route with method, path and handler. path may carry a
+// "#vN" suffix (e.g. "/users/:id#v2") to register handler as one of
+// several versioned variants of the same route; see VersionResolver.
 func (rt *Router) Handle(method, path string, handler http.Handler) {
 	if len(path) == 0 || path[0] != '/' {
 		panic(fmt.Errorf("router: path %q must begin with %q", path, "/"))
 	}
 
+	var version string
+	if hashIdx := strings.IndexByte(path, '#'); hashIdx != -1 {
+		version = path[hashIdx+1:]
+		path = path[:hashIdx]
+	}
+
+	rt.routed = true
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		handler = rt.middleware[i](handler)
+	}
+
 	// Get (or set) tree for method.
 	n := rt.trees[method]
 	if n == nil {
@@ -60,7 +100,7 @@ func (rt *Router) Handle(method, path string, handler http.Handler) {
 	for i, part := range parts {
 		s += "/"
 		if len(part) > 0 && part[0] == ':' { // It's a parameter.
-			n.makeChild(s, params, nil, nil, (i == 0 && s == "/")) // Make child without ":".
+			n.makeChild(s, params, nil, "", nil, (i == 0 && s == "/")) // Make child without ":".
 			part = part[1:]
 			reSep := strings.IndexByte(part, ':') // Search for a name/regexp separator.
 			var re *regexp.Regexp
@@ -88,10 +128,26 @@ func (rt *Router) Handle(method, path string, handler http.Handler) {
 			}
 			s += ":"               // Only keep colon to represent parameter in tree.
 			if i == len(parts)-1 { // Parameter is the last part: make it with handler.
-				n.makeChild(s, params, re, handler, false)
+				n.makeChild(s, params, re, version, handler, false)
 			} else {
-				n.makeChild(s, params, re, nil, false)
+				n.makeChild(s, params, re, "", nil, false)
+			}
+		} else if len(part) > 0 && part[0] == '*' { // It's a named catch-all.
+			if i != len(parts)-1 {
+				panic(fmt.Errorf("router: path %q has a catch-all before its final segment", path))
+			}
+			name := part[1:]
+			if name == "" {
+				panic(fmt.Errorf("router: path %q has an anonymous catch-all", path))
 			}
+			n.makeChild(s, params, nil, "", nil, (i == 0 && s == "/")) // Make child without "*".
+			if params == nil {
+				params = make(map[string]uint16)
+			}
+			params[name] = uint16(i) // Store parameter name with part index.
+			s += "*"                 // Only keep the star to represent the catch-all in tree.
+			cn := n.makeChild(s, params, nil, version, handler, false)
+			cn.catchAll = name
 		} else {
 			s += part
 			if i == len(parts)-1 { // Last part: make it with handler.
@@ -101,7 +157,10 @@ func (rt *Router) Handle(method, path string, handler http.Handler) {
 					}
 					params["*"] = uint16(i)
 				}
-				n.makeChild(s, params, nil, handler, (i == 0 && s == "/"))
+				cn := n.makeChild(s, params, nil, version, handler, (i == 0 && s == "/"))
+				if s != "/" && isWildcard(s) {
+					cn.catchAll = "*"
+				}
 			}
 		}
 	}
@@ -133,27 +192,72 @@ func (rt *Router) Delete(path string, handler http.Handler) {
 }
 
 func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	// Remove trailing slash.
-	if len(r.URL.Path) > 1 && r.URL.Path[len(r.URL.Path)-1] == '/' {
-		r.URL.Path = r.URL.Path[:len(r.URL.Path)-1]
-		http.Redirect(w, r, r.URL.String(), http.StatusMovedPermanently)
+	// The asterisk-form "OPTIONS *" request (RFC 7230 §5.3.4) isn't a
+	// path at all, so it must be recognised before any path
+	// canonicalisation runs: CleanPath would otherwise rewrite "*" into
+	// the literal segment "/*" and it would never match here. Like the
+	// path-based automatic OPTIONS reply below, it's opt-in via
+	// HandleOPTIONS.
+	if rt.HandleOPTIONS && r.Method == http.MethodOptions && r.URL.Path == "*" {
+		if rt.GlobalOPTIONS != nil {
+			rt.GlobalOPTIONS.ServeHTTP(w, r)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
 		return
 	}
 
-	// TODO: Handle OPTIONS request.
+	// Canonicalise the path (if enabled) and strip a trailing slash,
+	// issuing a single redirect if either normalisation changed it.
+	path := r.URL.Path
+	if rt.CleanPath {
+		path = CleanPath(path)
+	}
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+	if path != r.URL.Path {
+		r.URL.Path = path
+		http.Redirect(w, r, r.URL.String(), http.StatusMovedPermanently)
+		return
+	}
 
 	if n := rt.trees[r.Method]; n != nil {
 		n = n.findChild(r.URL.Path)
-		if n != nil && n.handler != nil {
+		if n != nil && len(n.handlers) > 0 {
+			handler, ok := n.handlerFor(rt.resolveVersion(r))
+			if !ok {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
 			// Store parameters in request's context.
 			if n.params != nil {
-				r = r.WithContext(context.WithValue(r.Context(), contextKeyParamsIdx, n.params))
+				params := buildParams(r.URL.Path, n.params, n.catchAll)
+				r = r.WithContext(context.WithValue(r.Context(), contextKeyParams, params))
 			}
-			n.handler.ServeHTTP(w, r)
+			handler.ServeHTTP(w, r)
 			return
 		}
 	}
 
+	if methods := rt.allowedMethods(r.URL.Path, r.Method); len(methods) > 0 {
+		if rt.HandleOPTIONS {
+			methods = append(methods, http.MethodOptions)
+			sort.Strings(methods)
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		if rt.HandleOPTIONS && r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if rt.MethodNotAllowedHandler != nil {
+			rt.MethodNotAllowedHandler.ServeHTTP(w, r)
+		} else {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
 	if rt.NotFoundHandler != nil {
 		rt.NotFoundHandler.ServeHTTP(w, r)
 	} else {
@@ -161,35 +265,70 @@ func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// allowedMethods returns, sorted, the HTTP methods other than exclude
+// that have a registered route for path. It is used to build the Allow
+// header for 405 Method Not Allowed responses and automatic OPTIONS
+// handling, reusing the same per-path lookup for both.
+func (rt *Router) allowedMethods(path, exclude string) []string {
+	var methods []string
+	for method, n := range rt.trees {
+		if method == exclude {
+			continue
+		}
+		if n.routeExists(path) {
+			methods = append(methods, method)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// resolveVersion returns the API version the client requested, using
+// rt.VersionResolver if set, or defaultVersionResolver otherwise.
+func (rt *Router) resolveVersion(r *http.Request) string {
+	if rt.VersionResolver != nil {
+		return rt.VersionResolver(r)
+	}
+	return defaultVersionResolver(r)
+}
+
+// defaultVersionResolver reads the client's requested version from an
+// X-Api-Version header, or else a "version" media-type parameter on the
+// Accept header (e.g. "application/vnd.api+json; version=2"), returning
+// it as "vN" to match the "#vN" suffix used when registering versioned
+// routes.
+func defaultVersionResolver(r *http.Request) string {
+	if v := r.Header.Get("X-Api-Version"); v != "" {
+		return normalizeVersion(v)
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if v := params["version"]; v != "" {
+			return normalizeVersion(v)
+		}
+	}
+	return ""
+}
+
+// normalizeVersion prefixes v with "v" if it isn't already, so versions
+// read from X-Api-Version ("v2") and from an Accept version parameter
+// ("2") resolve to the same "#vN" route suffix.
+func normalizeVersion(v string) string {
+	if v[0] != 'v' {
+		return "v" + v
+	}
+	return v
+}
+
 // Parameter returns the value of path parameter.
 // Result is empty if parameter doesn't exist.
+//
+// Deprecated: use ParamsFromContext instead.
 func Parameter(r *http.Request, key string) string {
-	params, ok := r.Context().Value(contextKeyParams).(map[string]string)
-	if ok { // Parameters already parsed.
-		return params[key]
-	}
-	paramsIdx, ok := r.Context().Value(contextKeyParamsIdx).(map[string]uint16)
-	if !ok {
-		return ""
-	}
-	params = make(map[string]string, len(paramsIdx))
-	parts := splitPath(r.URL.Path)
-	for name, idx := range paramsIdx {
-		switch name {
-		case "*":
-			for idx < uint16(len(parts)) {
-				params[name] += parts[idx]
-				if idx < uint16(len(parts))-1 {
-					params[name] += "/"
-				}
-				idx++
-			}
-		default:
-			params[name] = parts[idx]
-		}
-	}
-	*r = *r.WithContext(context.WithValue(r.Context(), contextKeyParams, params))
-	return params[key]
+	return ParamsFromContext(r.Context()).Get(key)
 }
 
 // isWildcard tells if s ends with '/'.
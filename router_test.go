@@ -0,0 +1,129 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestServeHTTPCleanPathRedirects checks that an unclean path is
+// redirected to its canonical form when CleanPath is enabled.
+func TestServeHTTPCleanPathRedirects(t *testing.T) {
+	rt := New()
+	rt.CleanPath = true
+	rt.Get("/a/c", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/a//b/../c", nil)
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMovedPermanently)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/a/c" {
+		t.Fatalf("Location = %q, want %q", loc, "/a/c")
+	}
+}
+
+// TestServeHTTPOptionsAsteriskNotCorruptedByCleanPath guards against
+// CleanPath rewriting the asterisk-form "OPTIONS *" request (where
+// r.URL.Path is the literal string "*", not a path) into "/*" before
+// it can be recognised as the special case it is.
+func TestServeHTTPOptionsAsteriskNotCorruptedByCleanPath(t *testing.T) {
+	rt := New()
+	rt.CleanPath = true
+	rt.HandleOPTIONS = true
+	var called bool
+	rt.GlobalOPTIONS = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "http://example.com", nil)
+	req.URL.Path = "*"
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusMovedPermanently {
+		t.Fatalf("OPTIONS * was redirected instead of reaching GlobalOPTIONS")
+	}
+	if !called {
+		t.Fatal("GlobalOPTIONS was not called for OPTIONS *")
+	}
+}
+
+// TestServeHTTPOptionsAsteriskRequiresHandleOPTIONS checks that "OPTIONS
+// *" is only handled automatically when HandleOPTIONS is enabled,
+// mirroring the opt-in automatic reply for path-based OPTIONS requests.
+func TestServeHTTPOptionsAsteriskRequiresHandleOPTIONS(t *testing.T) {
+	rt := New()
+	var called bool
+	rt.GlobalOPTIONS = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "http://example.com", nil)
+	req.URL.Path = "*"
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if called {
+		t.Fatal("GlobalOPTIONS was called for OPTIONS * despite HandleOPTIONS being false")
+	}
+	if rec.Code == http.StatusNoContent {
+		t.Fatalf("status = %d, want something other than a bare 204 with HandleOPTIONS false", rec.Code)
+	}
+}
+
+// TestServeHTTPMethodNotAllowed checks that a 405 response carries an
+// Allow header listing the other methods registered for the path.
+func TestServeHTTPMethodNotAllowed(t *testing.T) {
+	rt := New()
+	rt.Get("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rt.Post("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodDelete, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, POST" {
+		t.Fatalf("Allow = %q, want %q", allow, "GET, POST")
+	}
+}
+
+// TestServeHTTPAutomaticOptions checks that, with HandleOPTIONS enabled,
+// an OPTIONS request for a routed path gets an automatic 204 listing
+// the path's allowed methods (including OPTIONS itself), and that the
+// same request gets a 405 when HandleOPTIONS is left at its default.
+func TestServeHTTPAutomaticOptions(t *testing.T) {
+	rt := New()
+	rt.HandleOPTIONS = true
+	rt.Get("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rt.Post("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, OPTIONS, POST" {
+		t.Fatalf("Allow = %q, want %q", allow, "GET, OPTIONS, POST")
+	}
+
+	rt2 := New()
+	rt2.Get("/widgets", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req = httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	rec = httptest.NewRecorder()
+	rt2.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d with HandleOPTIONS false", rec.Code, http.StatusMethodNotAllowed)
+	}
+}